@@ -0,0 +1,7 @@
+// +k8s:deepcopy-gen=package,register
+// +groupName=oauthclientregistration.operator.openshift.io
+
+// Package v1alpha1 contains the OAuthClientRegistration API, which lets
+// operators declare additional OAuthClient objects for the oauth-server
+// to manage alongside the built-in bootstrapped clients.
+package v1alpha1
@@ -0,0 +1,128 @@
+package v1alpha1
+
+import (
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExternalConnectorType identifies the upstream identity provider an
+// OAuthClientRegistration federates to.
+type ExternalConnectorType string
+
+const (
+	ExternalConnectorTypeGitHub    ExternalConnectorType = "GitHub"
+	ExternalConnectorTypeGitLab    ExternalConnectorType = "GitLab"
+	ExternalConnectorTypeGoogle    ExternalConnectorType = "Google"
+	ExternalConnectorTypeBitbucket ExternalConnectorType = "Bitbucket"
+	ExternalConnectorTypeOIDC      ExternalConnectorType = "OIDC"
+)
+
+// ExternalConnector references an upstream identity provider that backs an
+// OAuthClientRegistration, mirroring the dex-style connector model so the
+// oauth-server can accept federated logins without a provider-specific
+// operator.
+type ExternalConnector struct {
+	// type is the upstream provider this connector talks to.
+	// +kubebuilder:validation:Required
+	Type ExternalConnectorType `json:"type"`
+
+	// issuerURL is the OIDC issuer URL. Only used when type is OIDC.
+	// +optional
+	IssuerURL string `json:"issuerURL,omitempty"`
+
+	// clientIDSecretRef names a Secret holding the upstream provider's
+	// clientID and clientSecret keys. OAuthClientRegistration is
+	// cluster-scoped, so clientIDSecretNamespace must also be set.
+	// +kubebuilder:validation:Required
+	ClientIDSecretRef string `json:"clientIDSecretRef"`
+
+	// clientIDSecretNamespace is the namespace of the Secret named by
+	// clientIDSecretRef.
+	// +kubebuilder:validation:Required
+	ClientIDSecretNamespace string `json:"clientIDSecretNamespace"`
+}
+
+// OAuthClientRegistrationSpec describes an OAuthClient the operator should
+// reconcile in addition to the three hard-coded bootstrapped clients.
+type OAuthClientRegistrationSpec struct {
+	// secret is the client secret. An empty value means the secret is
+	// unmanaged and left alone once the client is created.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// secretRotateAfter is a duration (time.ParseDuration syntax) after
+	// which the controller regenerates this client's secret. Left unset,
+	// the secret is never automatically rotated.
+	// +optional
+	SecretRotateAfter string `json:"secretRotateAfter,omitempty"`
+
+	// grantMethod controls how the oauth-server handles client
+	// authorization grants, mirroring oauthv1.OAuthClient.GrantMethod.
+	// +kubebuilder:validation:Required
+	GrantMethod oauthv1.GrantHandlerType `json:"grantMethod"`
+
+	// respondWithChallenges mirrors oauthv1.OAuthClient.RespondWithChallenges.
+	// +optional
+	RespondWithChallenges bool `json:"respondWithChallenges,omitempty"`
+
+	// redirectURIs mirrors oauthv1.OAuthClient.RedirectURIs. Left empty for
+	// an externalConnector client, the oauth-server's connector callback
+	// URI is used instead.
+	// +optional
+	RedirectURIs []string `json:"redirectURIs,omitempty"`
+
+	// scopeRestrictions mirrors oauthv1.OAuthClient.ScopeRestrictions.
+	// +optional
+	ScopeRestrictions []oauthv1.ScopeRestriction `json:"scopeRestrictions,omitempty"`
+
+	// externalConnector, if set, federates this client to an upstream
+	// identity provider instead of authenticating locally.
+	// +optional
+	ExternalConnector *ExternalConnector `json:"externalConnector,omitempty"`
+
+	// requirePKCE requires a code_challenge on every authorize request for
+	// this client, per RFC 7636. Recommended for any public client.
+	// +optional
+	RequirePKCE bool `json:"requirePKCE,omitempty"`
+
+	// allowedChallengeMethods restricts which PKCE code_challenge_method
+	// values the client accepts when requirePKCE is true. Defaults to
+	// ["S256"] when unset. Downgrading an S256-only client to also accept
+	// "plain" is rejected by the controller.
+	// +optional
+	AllowedChallengeMethods []string `json:"allowedChallengeMethods,omitempty"`
+}
+
+// OAuthClientRegistrationStatus surfaces the reconciliation state of the
+// registered OAuthClient.
+type OAuthClientRegistrationStatus struct {
+	// conditions represents the latest available observations of the
+	// registration's reconciliation state.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OAuthClientRegistration declares an additional OAuthClient for
+// oauthclientscontroller to reconcile, including optionally federating it
+// to an external identity provider.
+type OAuthClientRegistration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OAuthClientRegistrationSpec   `json:"spec"`
+	Status OAuthClientRegistrationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// OAuthClientRegistrationList is a list of OAuthClientRegistration objects.
+type OAuthClientRegistrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []OAuthClientRegistration `json:"items"`
+}
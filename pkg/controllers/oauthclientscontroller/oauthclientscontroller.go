@@ -0,0 +1,793 @@
+// Package oauthclientscontroller ensures the well-known OAuthClient objects
+// consumed by the OpenShift oauth-server exist and are kept in sync with the
+// public URL of the oauth-server route.
+package oauthclientscontroller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	configv1informers "github.com/openshift/client-go/config/informers/externalversions/config/v1"
+	configv1listers "github.com/openshift/client-go/config/listers/config/v1"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	oauthv1informers "github.com/openshift/client-go/oauth/informers/externalversions/oauth/v1"
+	oauthv1listers "github.com/openshift/client-go/oauth/listers/oauth/v1"
+	routev1informers "github.com/openshift/client-go/route/informers/externalversions/route/v1"
+	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
+	"github.com/openshift/library-go/pkg/controller/factory"
+	"github.com/openshift/library-go/pkg/oauth/oauthdiscovery"
+	"github.com/openshift/library-go/pkg/operator/events"
+
+	oauthclientregistrationv1alpha1 "github.com/openshift/cluster-authentication-operator/pkg/apis/oauthclientregistration/v1alpha1"
+	oauthclientregistrationinformers "github.com/openshift/cluster-authentication-operator/pkg/generated/informers/oauthclientregistration/v1alpha1"
+	oauthclientregistrationlisters "github.com/openshift/cluster-authentication-operator/pkg/generated/listers/oauthclientregistration/v1alpha1"
+)
+
+const (
+	oauthServerNamespace = "openshift-authentication"
+	oauthServerRouteName = "oauth-openshift"
+
+	openShiftBrowserClientID     = "openshift-browser-client"
+	openShiftChallengingClientID = "openshift-challenging-client"
+	openShiftCLIClientID         = "openshift-cli-client"
+	openShiftM2MClientID         = "openshift-m2m-client"
+
+	// rotateAfterAnnotation names a duration (time.ParseDuration syntax)
+	// after which ensureOAuthClient regenerates an OAuthClient's secret.
+	rotateAfterAnnotation = "oauth.openshift.io/rotate-after"
+	// lastRotatedAnnotation records the RFC3339 timestamp of the last
+	// secret rotation performed by ensureOAuthClient.
+	lastRotatedAnnotation = "oauth.openshift.io/rotated-at"
+
+	// requirePKCEAnnotation, consumed by the oauth-server, marks a public
+	// OAuthClient as requiring a code_challenge on every authorize request
+	// per RFC 7636.
+	requirePKCEAnnotation = "oauth.openshift.io/require-pkce"
+	// allowedChallengeMethodsAnnotation lists the comma-separated
+	// code_challenge_method values (plain, S256) an OAuthClient accepts.
+	allowedChallengeMethodsAnnotation = "oauth.openshift.io/allowed-challenge-methods"
+
+	pkceMethodPlain = "plain"
+	pkceMethodS256  = "S256"
+
+	// browserClientSecretRotateAfter is how long the openshift-browser-client
+	// secret is kept before it is automatically regenerated.
+	browserClientSecretRotateAfter = 720 * time.Hour
+
+	// tokenTrustIssuersAnnotation lists the comma-separated OIDC issuers the
+	// oauth-server trusts for JWT-bearer grant assertions (RFC 7523)
+	// presented to openShiftM2MClientID.
+	tokenTrustIssuersAnnotation = "oauth.openshift.io/token-trust-issuers"
+	// tokenTrustJWKSURLAnnotation names the JWKS endpoint used to verify the
+	// signature of incoming JWT-bearer assertions.
+	tokenTrustJWKSURLAnnotation = "oauth.openshift.io/token-trust-jwks-url"
+	// tokenTrustCacheExpirationAnnotation records, in time.ParseDuration
+	// syntax, the TokenTrustCacheExpiration that ensureM2MOAuthClients uses
+	// to decide when the trusted-issuer ConfigMap is re-verified and
+	// resynced. The controller itself does not read it back.
+	tokenTrustCacheExpirationAnnotation = "oauth.openshift.io/token-trust-cache-expiration"
+	// tokenTrustSyncedAtAnnotation records the RFC3339 timestamp of the last
+	// successful trusted-issuer ConfigMap sync.
+	tokenTrustSyncedAtAnnotation = "oauth.openshift.io/token-trust-synced-at"
+
+	// trustedIssuersConfigMapName holds the trusted-issuer configuration
+	// consumed by the oauth-server's config observer.
+	trustedIssuersConfigMapName = "trusted-token-issuers"
+)
+
+// managedAnnotations lists the well-known annotations ensureOAuthClient
+// reconciles on top of the regular OAuthClient spec fields.
+var managedAnnotations = []string{
+	rotateAfterAnnotation,
+	lastRotatedAnnotation,
+	requirePKCEAnnotation,
+	allowedChallengeMethodsAnnotation,
+	tokenTrustIssuersAnnotation,
+	tokenTrustJWKSURLAnnotation,
+	tokenTrustCacheExpirationAnnotation,
+}
+
+// oauthsClientsController reconciles the OAuthClient objects the
+// oauth-server depends on: the three hard-coded bootstrapped clients plus
+// any additional clients declared through OAuthClientRegistration objects.
+type oauthsClientsController struct {
+	oauthClientClient oauthv1client.OAuthClientInterface
+	oauthClientLister oauthv1listers.OAuthClientLister
+	routeLister       routev1listers.RouteLister
+	ingressLister     configv1listers.IngressLister
+
+	oauthClientRegistrationLister oauthclientregistrationlisters.OAuthClientRegistrationLister
+
+	configMapClient corev1client.ConfigMapsGetter
+	configMapLister corev1listers.ConfigMapLister
+
+	tokenTrustVerification TokenTrustVerification
+	verifyJWKS             func(url string) error
+}
+
+// TokenTrustVerification is the operator-configured trust policy for JWT-
+// bearer grant assertions (RFC 7523) presented to openShiftM2MClientID. An
+// empty IssuerAllowlist disables the M2M client entirely.
+type TokenTrustVerification struct {
+	// IssuerAllowlist is the set of OIDC issuers the oauth-server accepts
+	// JWT-bearer assertions from.
+	IssuerAllowlist []string
+	// JWKSURL is verified reachable before the trust configuration is
+	// synced, and is used by the oauth-server to validate assertion
+	// signatures.
+	JWKSURL string
+	// TokenTrustCacheExpiration controls how long ensureM2MOAuthClients
+	// waits before re-verifying JWKSURL and resyncing the trusted-issuer
+	// ConfigMap, even when the configured issuers haven't changed.
+	TokenTrustCacheExpiration time.Duration
+}
+
+// NewOAuthClientsController returns a controller that keeps the
+// bootstrapped and registered OAuthClient objects in sync with the public
+// hostname of the oauth-server route.
+func NewOAuthClientsController(
+	oauthClientClient oauthv1client.OAuthClientsGetter,
+	oauthClientInformer oauthv1informers.OAuthClientInformer,
+	routeInformer routev1informers.RouteInformer,
+	ingressInformer configv1informers.IngressInformer,
+	oauthClientRegistrationInformer oauthclientregistrationinformers.OAuthClientRegistrationInformer,
+	configMapClient corev1client.ConfigMapsGetter,
+	configMapInformer corev1informers.ConfigMapInformer,
+	tokenTrustVerification TokenTrustVerification,
+	recorder events.Recorder,
+) factory.Controller {
+	c := &oauthsClientsController{
+		oauthClientClient:             oauthClientClient.OAuthClients(),
+		oauthClientLister:             oauthClientInformer.Lister(),
+		routeLister:                   routeInformer.Lister(),
+		ingressLister:                 ingressInformer.Lister(),
+		oauthClientRegistrationLister: oauthClientRegistrationInformer.Lister(),
+		configMapClient:               configMapClient,
+		configMapLister:               configMapInformer.Lister(),
+		tokenTrustVerification:        tokenTrustVerification,
+		verifyJWKS:                    verifyTokenTrustJWKS,
+	}
+
+	return factory.New().
+		WithSync(c.sync).
+		WithInformers(
+			oauthClientInformer.Informer(),
+			routeInformer.Informer(),
+			ingressInformer.Informer(),
+			oauthClientRegistrationInformer.Informer(),
+			configMapInformer.Informer(),
+		).
+		ResyncEvery(time.Minute).
+		ToController("OAuthClientsController", recorder)
+}
+
+func (c *oauthsClientsController) sync(ctx context.Context, syncCtx factory.SyncContext) error {
+	ingressConfig, err := c.getIngressConfig()
+	if err != nil {
+		return err
+	}
+
+	hosts, err := c.getCanonicalRouteHosts()
+	if err != nil {
+		return err
+	}
+
+	if override := componentRouteHostname(ingressConfig); len(override) > 0 {
+		if !sets.NewString(hosts...).Has(override) {
+			return fmt.Errorf("route %s/%s has no admitted ingress for configured hostname %q",
+				oauthServerNamespace, oauthServerRouteName, override)
+		}
+	}
+
+	if err := c.ensureBootstrappedOAuthClients(ctx, hosts); err != nil {
+		return err
+	}
+
+	if err := c.ensureRegisteredOAuthClients(ctx, hosts); err != nil {
+		return err
+	}
+
+	if err := c.ensureM2MOAuthClients(ctx); err != nil {
+		return err
+	}
+
+	// Secret rotation is annotation-driven rather than event-driven. On top
+	// of the one-minute periodic resync, queue a resync for when the
+	// openshift-browser-client rotation interval elapses.
+	if queue := syncCtx.Queue(); queue != nil {
+		queue.AddAfter(syncCtx.QueueKey(), browserClientSecretRotateAfter)
+	}
+
+	return nil
+}
+
+// getIngressConfig returns the cluster-scoped Ingress config, erroring out
+// if it is missing or has not yet been populated with a domain.
+func (c *oauthsClientsController) getIngressConfig() (*configv1.Ingress, error) {
+	ingress, err := c.ingressLister.Get("cluster")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingress config: %w", err)
+	}
+
+	if len(ingress.Spec.Domain) == 0 {
+		return nil, fmt.Errorf("ingress config has no domain set")
+	}
+
+	return ingress, nil
+}
+
+// componentRouteHostname returns the operator-configured hostname override
+// for the oauth-server route, or the empty string if none was set.
+func componentRouteHostname(ingress *configv1.Ingress) string {
+	for _, componentRoute := range ingress.Spec.ComponentRoutes {
+		if componentRoute.Namespace == oauthServerNamespace && componentRoute.Name == oauthServerRouteName {
+			return componentRoute.Hostname
+		}
+	}
+	return ""
+}
+
+// connectorCallbackURL builds the redirect URI an external identity
+// connector callback lands on for a given registered OAuth client.
+func connectorCallbackURL(host, clientName string) string {
+	return fmt.Sprintf("https://%s/oauth2callback/%s", host, clientName)
+}
+
+// tokenDisplayURLs and tokenImplicitURLs fan the oauth-server's well-known
+// token endpoints out across every admitted route hostname.
+
+func tokenDisplayURLs(hosts []string) []string {
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, oauthdiscovery.OpenShiftOAuthTokenDisplayURL(host))
+	}
+	return urls
+}
+
+func tokenImplicitURLs(hosts []string) []string {
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, oauthdiscovery.OpenShiftOAuthTokenImplicitURL(host))
+	}
+	return urls
+}
+
+func connectorCallbackURLs(hosts []string, clientName string) []string {
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, connectorCallbackURL(host, clientName))
+	}
+	return urls
+}
+
+// getCanonicalRouteHosts returns the sorted, deduplicated set of hostnames
+// admitted by every ingress controller that has accepted the
+// oauth-openshift route. In sharded router deployments the route can be
+// admitted by several ingress controllers under different canonical
+// hostnames, and all of them are valid OAuthClient redirect targets.
+func (c *oauthsClientsController) getCanonicalRouteHosts() ([]string, error) {
+	route, err := c.routeLister.Routes(oauthServerNamespace).Get(oauthServerRouteName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route %s/%s: %w", oauthServerNamespace, oauthServerRouteName, err)
+	}
+
+	hosts := sets.NewString()
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				hosts.Insert(ingress.Host)
+			}
+		}
+	}
+
+	if hosts.Len() == 0 {
+		return nil, fmt.Errorf("route %s/%s has no admitted ingress", oauthServerNamespace, oauthServerRouteName)
+	}
+
+	return hosts.List(), nil
+}
+
+// ensureBootstrappedOAuthClients reconciles the three OAuthClient objects
+// the oauth-server always expects to exist. hosts is every admitted
+// canonical hostname of the oauth-openshift route, across all router
+// shards, and is fanned out into the browser and challenging clients'
+// redirect URIs; the CLI client keeps its fixed loopback redirect URIs.
+func (c *oauthsClientsController) ensureBootstrappedOAuthClients(ctx context.Context, hosts []string) error {
+	clients := []oauthv1.OAuthClient{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: openShiftBrowserClientID,
+				Annotations: map[string]string{
+					rotateAfterAnnotation:             browserClientSecretRotateAfter.String(),
+					requirePKCEAnnotation:             "true",
+					allowedChallengeMethodsAnnotation: pkceMethodS256,
+				},
+			},
+			RespondWithChallenges: false,
+			RedirectURIs:          tokenDisplayURLs(hosts),
+			GrantMethod:           oauthv1.GrantHandlerAuto,
+		},
+		{
+			ObjectMeta:            metav1.ObjectMeta{Name: openShiftChallengingClientID},
+			RespondWithChallenges: true,
+			RedirectURIs:          tokenImplicitURLs(hosts),
+			GrantMethod:           oauthv1.GrantHandlerAuto,
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: openShiftCLIClientID,
+				Annotations: map[string]string{
+					requirePKCEAnnotation:             "true",
+					allowedChallengeMethodsAnnotation: pkceMethodS256,
+				},
+			},
+			RedirectURIs: []string{"http://127.0.0.1/callback", "http://[::1]/callback"},
+			GrantMethod:  oauthv1.GrantHandlerAuto,
+		},
+	}
+
+	for _, client := range clients {
+		if err := ensureOAuthClient(ctx, c.oauthClientClient, client); err != nil {
+			return fmt.Errorf("failed to ensure bootstrapped oauth client %q: %w", client.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ensureOAuthClient creates required if it does not exist yet, otherwise it
+// reconciles the mutable fields of the existing client towards required.
+// An empty required.Secret means the secret is unmanaged and is left alone,
+// unless required carries a rotateAfterAnnotation whose interval has
+// elapsed, in which case a fresh secret is generated regardless.
+func ensureOAuthClient(ctx context.Context, client oauthv1client.OAuthClientInterface, required oauthv1.OAuthClient) error {
+	if len(required.Name) == 0 {
+		return fmt.Errorf("required oauth client must have a name")
+	}
+	if len(required.GrantMethod) == 0 {
+		return fmt.Errorf("required oauth client %q must have a grant method", required.Name)
+	}
+
+	toCreate := applyScheduledRotation(&required, nil)
+	_, err := client.Create(ctx, toCreate, metav1.CreateOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, err := client.Get(ctx, required.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if isPKCEDowngrade(existing, &required) {
+		return fmt.Errorf("refusing to update oauth client %q: would relax PKCE enforcement from requirePKCE=true methods=%v to requirePKCE=%v methods=%v",
+			required.Name, allowedChallengeMethods(existing), required.Annotations[requirePKCEAnnotation] == "true", allowedChallengeMethods(&required))
+	}
+
+	desired := applyScheduledRotation(&required, existing)
+	updated, changed := applyOAuthClientUpdates(existing, desired)
+	if !changed {
+		return nil
+	}
+
+	_, err = client.Update(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
+
+// applyScheduledRotation returns a copy of required with a freshly
+// generated secret and an updated lastRotatedAnnotation when required's
+// rotateAfterAnnotation says a rotation is due. existing is nil when the
+// client doesn't exist yet, in which case a rotation policy always forces
+// the initial secret to be generated here rather than left blank.
+//
+// required is rebuilt from scratch on every sync and so never carries
+// lastRotatedAnnotation itself; when a rotation isn't due yet, that
+// annotation is carried forward from existing so applyOAuthClientUpdates
+// doesn't see a diff and strip it, which would make the next sync think a
+// rotation is overdue again. rotateAfterAnnotation is left as required set
+// it, so a legitimate change to the configured rotation interval still
+// takes effect on the next sync instead of being silently reverted.
+func applyScheduledRotation(required, existing *oauthv1.OAuthClient) *oauthv1.OAuthClient {
+	interval, ok := rotationInterval(required)
+	if !ok {
+		return required
+	}
+
+	if existing != nil {
+		if lastRotated, ok := lastRotated(existing); ok && time.Since(lastRotated) < interval {
+			out := required.DeepCopy()
+			if out.Annotations == nil {
+				out.Annotations = map[string]string{}
+			}
+			if value, ok := existing.Annotations[lastRotatedAnnotation]; ok {
+				out.Annotations[lastRotatedAnnotation] = value
+			}
+			return out
+		}
+	}
+
+	out := required.DeepCopy()
+	out.Secret = base64.RawURLEncoding.EncodeToString(randomBits(256))
+	if out.Annotations == nil {
+		out.Annotations = map[string]string{}
+	}
+	out.Annotations[lastRotatedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	return out
+}
+
+func rotationInterval(client *oauthv1.OAuthClient) (time.Duration, bool) {
+	raw, ok := client.Annotations[rotateAfterAnnotation]
+	if !ok || len(raw) == 0 {
+		return 0, false
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, false
+	}
+	return interval, true
+}
+
+// allowedChallengeMethods returns the PKCE code_challenge_method values an
+// OAuthClient accepts, as declared by allowedChallengeMethodsAnnotation.
+func allowedChallengeMethods(client *oauthv1.OAuthClient) []string {
+	raw, ok := client.Annotations[allowedChallengeMethodsAnnotation]
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// isPKCEDowngrade reports whether desired would relax PKCE enforcement on a
+// client that currently requires it, either by dropping requirePKCE
+// entirely or by widening an S256-only client to also accept the weaker
+// "plain" challenge method.
+func isPKCEDowngrade(existing, desired *oauthv1.OAuthClient) bool {
+	if existing.Annotations[requirePKCEAnnotation] != "true" {
+		return false
+	}
+
+	if desired.Annotations[requirePKCEAnnotation] != "true" {
+		return true
+	}
+
+	existingMethods := allowedChallengeMethods(existing)
+	if !containsString(existingMethods, pkceMethodS256) || containsString(existingMethods, pkceMethodPlain) {
+		return false
+	}
+
+	return containsString(allowedChallengeMethods(desired), pkceMethodPlain)
+}
+
+func lastRotated(client *oauthv1.OAuthClient) (time.Time, bool) {
+	raw, ok := client.Annotations[lastRotatedAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+// applyOAuthClientUpdates returns a copy of existing with the mutable
+// fields reconciled towards required, and whether anything changed.
+func applyOAuthClientUpdates(existing, required *oauthv1.OAuthClient) (*oauthv1.OAuthClient, bool) {
+	updated := existing.DeepCopy()
+	changed := false
+
+	if len(required.Secret) > 0 && !secretsEqual(required.Secret, existing.Secret) {
+		updated.Secret = required.Secret
+		changed = true
+	}
+	if required.RespondWithChallenges != existing.RespondWithChallenges {
+		updated.RespondWithChallenges = required.RespondWithChallenges
+		changed = true
+	}
+	if !stringSlicesEqual(required.RedirectURIs, existing.RedirectURIs) {
+		updated.RedirectURIs = required.RedirectURIs
+		changed = true
+	}
+	if required.GrantMethod != existing.GrantMethod {
+		updated.GrantMethod = required.GrantMethod
+		changed = true
+	}
+	if !scopeRestrictionsEqual(required.ScopeRestrictions, existing.ScopeRestrictions) {
+		updated.ScopeRestrictions = required.ScopeRestrictions
+		changed = true
+	}
+	for _, key := range managedAnnotations {
+		if required.Annotations[key] == existing.Annotations[key] {
+			continue
+		}
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		setOrDeleteAnnotation(updated.Annotations, key, required.Annotations[key])
+		changed = true
+	}
+
+	return updated, changed
+}
+
+func setOrDeleteAnnotation(annotations map[string]string, key, value string) {
+	if len(value) == 0 {
+		delete(annotations, key)
+		return
+	}
+	annotations[key] = value
+}
+
+// secretsEqual reports whether a and b are the same secret, compared in
+// constant time to avoid leaking timing information about a valid prefix.
+func secretsEqual(a, b string) bool {
+	return len(a) == len(b) && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func scopeRestrictionsEqual(a, b []oauthv1.ScopeRestriction) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !stringSlicesEqual(a[i].ExactValues, b[i].ExactValues) {
+			return false
+		}
+	}
+	return true
+}
+
+// randomBits returns a cryptographically random byte slice holding at
+// least n bits of entropy.
+func randomBits(n int) []byte {
+	size := n / 8
+	if n%8 != 0 {
+		size++
+	}
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // rand should never fail
+	}
+	return b
+}
+
+// oauthClientRegistrationToClient renders an OAuthClientRegistration's spec
+// as the desired OAuthClient that ensureOAuthClient reconciles towards.
+func oauthClientRegistrationToClient(registration *oauthclientregistrationv1alpha1.OAuthClientRegistration) oauthv1.OAuthClient {
+	spec := registration.Spec
+
+	annotations := map[string]string{}
+	if spec.RequirePKCE {
+		methods := spec.AllowedChallengeMethods
+		if len(methods) == 0 {
+			methods = []string{pkceMethodS256}
+		}
+		annotations[requirePKCEAnnotation] = "true"
+		annotations[allowedChallengeMethodsAnnotation] = strings.Join(methods, ",")
+	}
+	if len(spec.SecretRotateAfter) > 0 {
+		annotations[rotateAfterAnnotation] = spec.SecretRotateAfter
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	return oauthv1.OAuthClient{
+		ObjectMeta:            metav1.ObjectMeta{Name: registration.Name, Annotations: annotations},
+		Secret:                spec.Secret,
+		RespondWithChallenges: spec.RespondWithChallenges,
+		RedirectURIs:          spec.RedirectURIs,
+		GrantMethod:           spec.GrantMethod,
+		ScopeRestrictions:     spec.ScopeRestrictions,
+	}
+}
+
+// ensureRegisteredOAuthClients reconciles the OAuthClient objects declared
+// through OAuthClientRegistration CRs, in addition to the hard-coded
+// bootstrapped clients. This lets operators register additional clients
+// (including ones backed by an external identity connector) without
+// shipping a dedicated operator per upstream provider.
+func (c *oauthsClientsController) ensureRegisteredOAuthClients(ctx context.Context, hosts []string) error {
+	registrations, err := c.oauthClientRegistrationLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list oauth client registrations: %w", err)
+	}
+
+	for _, registration := range registrations {
+		desired := oauthClientRegistrationToClient(registration)
+		if len(desired.RedirectURIs) == 0 && registration.Spec.ExternalConnector != nil {
+			desired.RedirectURIs = connectorCallbackURLs(hosts, registration.Name)
+		}
+
+		if err := ensureOAuthClient(ctx, c.oauthClientClient, desired); err != nil {
+			return fmt.Errorf("failed to ensure registered oauth client %q: %w", registration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ensureM2MOAuthClients reconciles the machine-to-machine OAuth client used
+// for JWT-bearer grant assertions (RFC 7523), along with the ConfigMap of
+// trusted issuers the oauth-server's config observer consumes to validate
+// them. An empty IssuerAllowlist tears down the M2M client and ConfigMap,
+// since there is no trust policy left for the oauth-server to enforce.
+func (c *oauthsClientsController) ensureM2MOAuthClients(ctx context.Context) error {
+	trust := c.tokenTrustVerification
+	if len(trust.IssuerAllowlist) == 0 {
+		return c.removeM2MOAuthClients(ctx)
+	}
+
+	issuers := append([]string(nil), trust.IssuerAllowlist...)
+	sort.Strings(issuers)
+
+	client := oauthv1.OAuthClient{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: openShiftM2MClientID,
+			Annotations: map[string]string{
+				tokenTrustIssuersAnnotation:         strings.Join(issuers, ","),
+				tokenTrustJWKSURLAnnotation:         trust.JWKSURL,
+				tokenTrustCacheExpirationAnnotation: trust.TokenTrustCacheExpiration.String(),
+			},
+		},
+		GrantMethod: oauthv1.GrantHandlerAuto,
+	}
+
+	if err := ensureOAuthClient(ctx, c.oauthClientClient, client); err != nil {
+		return fmt.Errorf("failed to ensure bootstrapped oauth client %q: %w", client.Name, err)
+	}
+
+	if err := c.ensureTrustedIssuersConfigMap(ctx, trust, issuers); err != nil {
+		return fmt.Errorf("failed to sync trusted token issuers: %w", err)
+	}
+
+	return nil
+}
+
+// removeM2MOAuthClients deletes openShiftM2MClientID and the trusted-issuer
+// ConfigMap, called once TokenTrustVerification.IssuerAllowlist goes back to
+// empty so disabling M2M doesn't leave stale trust data behind.
+func (c *oauthsClientsController) removeM2MOAuthClients(ctx context.Context) error {
+	if err := c.oauthClientClient.Delete(ctx, openShiftM2MClientID, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete oauth client %q: %w", openShiftM2MClientID, err)
+	}
+
+	if err := c.configMapClient.ConfigMaps(oauthServerNamespace).Delete(ctx, trustedIssuersConfigMapName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete configmap %s/%s: %w", oauthServerNamespace, trustedIssuersConfigMapName, err)
+	}
+
+	return nil
+}
+
+// ensureTrustedIssuersConfigMap keeps the trusted-issuer ConfigMap in sync
+// with trust, re-verifying the JWKS endpoint and resyncing whenever the
+// configured issuers change or the cache expiration has elapsed.
+func (c *oauthsClientsController) ensureTrustedIssuersConfigMap(ctx context.Context, trust TokenTrustVerification, issuers []string) error {
+	desiredData := map[string]string{
+		"issuers": strings.Join(issuers, ","),
+		"jwksURL": trust.JWKSURL,
+	}
+
+	existing, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Get(ctx, trustedIssuersConfigMapName, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get configmap %s/%s: %w", oauthServerNamespace, trustedIssuersConfigMapName, err)
+	}
+
+	if err == nil && mapsEqual(existing.Data, desiredData) {
+		if syncedAt, ok := lastSynced(existing); ok && time.Since(syncedAt) < trust.TokenTrustCacheExpiration {
+			return nil
+		}
+	}
+
+	if verifyErr := c.verifyJWKS(trust.JWKSURL); verifyErr != nil {
+		return fmt.Errorf("refusing to sync trusted token issuers: %w", verifyErr)
+	}
+
+	if apierrors.IsNotFound(err) {
+		toCreate := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        trustedIssuersConfigMapName,
+				Namespace:   oauthServerNamespace,
+				Annotations: map[string]string{tokenTrustSyncedAtAnnotation: time.Now().UTC().Format(time.RFC3339)},
+			},
+			Data: desiredData,
+		}
+		_, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Create(ctx, toCreate, metav1.CreateOptions{})
+		return err
+	}
+
+	toUpdate := existing.DeepCopy()
+	toUpdate.Data = desiredData
+	if toUpdate.Annotations == nil {
+		toUpdate.Annotations = map[string]string{}
+	}
+	toUpdate.Annotations[tokenTrustSyncedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+	_, err = c.configMapClient.ConfigMaps(oauthServerNamespace).Update(ctx, toUpdate, metav1.UpdateOptions{})
+	return err
+}
+
+func lastSynced(configMap *corev1.ConfigMap) (time.Time, bool) {
+	raw, ok := configMap.Annotations[tokenTrustSyncedAtAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return parsed, true
+}
+
+func mapsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyTokenTrustJWKS reports an error unless a GET of url answers with
+// 200 OK, so an unreachable JWKS endpoint is rejected before the
+// oauth-server is told to rely on it. The response body is not checked to
+// be a JWKS document.
+func verifyTokenTrustJWKS(url string) error {
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS from %q: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS endpoint %q returned unexpected status %d", url, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -7,11 +7,16 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -25,6 +30,9 @@ import (
 	routev1listers "github.com/openshift/client-go/route/listers/route/v1"
 	"github.com/openshift/library-go/pkg/oauth/oauthdiscovery"
 	"github.com/openshift/library-go/pkg/operator/events"
+
+	oauthclientregistrationv1alpha1 "github.com/openshift/cluster-authentication-operator/pkg/apis/oauthclientregistration/v1alpha1"
+	oauthclientregistrationlisters "github.com/openshift/cluster-authentication-operator/pkg/generated/listers/oauthclientregistration/v1alpha1"
 )
 
 const (
@@ -50,6 +58,16 @@ var (
 		},
 	}
 
+	ingressStaleComponentRouteOverride = &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.IngressSpec{
+			Domain: "test.com",
+			ComponentRoutes: []configv1.ComponentRouteSpec{
+				{Namespace: "openshift-authentication", Name: "oauth-openshift", Hostname: "stale-override.test.com"},
+			},
+		},
+	}
+
 	ingressEmptyDomain = &configv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
 		Spec:       configv1.IngressSpec{Domain: ""},
@@ -73,6 +91,63 @@ var (
 	routeUnexpectedNamespaceAndName = &routev1.Route{
 		ObjectMeta: metav1.ObjectMeta{Name: "not-oauth-openshift", Namespace: "not-openshift-authentication"},
 	}
+
+	routeMultiShard = &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "oauth-openshift", Namespace: "openshift-authentication"},
+		Spec:       routev1.RouteSpec{Host: masterPublicURL},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{
+					Host: masterPublicURL,
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue},
+					},
+				},
+				{
+					Host: "oauth-openshift.shard2.test.com",
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+		},
+	}
+
+	routeMixedAdmission = &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "oauth-openshift", Namespace: "openshift-authentication"},
+		Spec:       routev1.RouteSpec{Host: masterPublicURL},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{
+					Host: masterPublicURL,
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: corev1.ConditionTrue},
+					},
+				},
+				{
+					Host: "oauth-openshift.rejected-shard.test.com",
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: corev1.ConditionFalse},
+					},
+				},
+			},
+		},
+	}
+
+	routeNoAdmittedIngress = &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "oauth-openshift", Namespace: "openshift-authentication"},
+		Spec:       routev1.RouteSpec{Host: masterPublicURL},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{
+					Host: masterPublicURL,
+					Conditions: []routev1.RouteIngressCondition{
+						{Type: routev1.RouteAdmitted, Status: corev1.ConditionFalse},
+					},
+				},
+			},
+		},
+	}
 )
 
 type fakeSyncContext struct{}
@@ -105,12 +180,42 @@ func newRouteLister(t *testing.T, routes ...*routev1.Route) routev1listers.Route
 	return routev1listers.NewRouteLister(routeIndexer)
 }
 
+func newOAuthClientRegistrationLister(t *testing.T, registrations ...*oauthclientregistrationv1alpha1.OAuthClientRegistration) oauthclientregistrationlisters.OAuthClientRegistrationLister {
+	registrationIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	for _, registration := range registrations {
+		if err := registrationIndexer.Add(registration); err != nil {
+			t.Fatalf("got unexpected err when setting up test oauth client registration: %v", err)
+		}
+	}
+
+	return oauthclientregistrationlisters.NewOAuthClientRegistrationLister(registrationIndexer)
+}
+
+func newConfigMapLister(t *testing.T, configMaps ...*corev1.ConfigMap) corev1listers.ConfigMapLister {
+	configMapIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	for _, configMap := range configMaps {
+		if err := configMapIndexer.Add(configMap); err != nil {
+			t.Fatalf("got unexpected err when setting up test configmap: %v", err)
+		}
+	}
+
+	return corev1listers.NewConfigMapLister(configMapIndexer)
+}
+
 func newTestOAuthsClientsController(t *testing.T) *oauthsClientsController {
+	fakeKubeClient := fakekubeclient.NewSimpleClientset()
+
 	return &oauthsClientsController{
-		oauthClientClient: fakeoauthclient.NewSimpleClientset().OauthV1().OAuthClients(),
-		oauthClientLister: oauthv1listers.NewOAuthClientLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
-		routeLister:       newRouteLister(t, defaultRoute),
-		ingressLister:     newIngressLister(t, defaultIngress),
+		oauthClientClient:             fakeoauthclient.NewSimpleClientset().OauthV1().OAuthClients(),
+		oauthClientLister:             oauthv1listers.NewOAuthClientLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})),
+		routeLister:                   newRouteLister(t, defaultRoute),
+		ingressLister:                 newIngressLister(t, defaultIngress),
+		oauthClientRegistrationLister: newOAuthClientRegistrationLister(t),
+		configMapClient:               fakeKubeClient.CoreV1(),
+		configMapLister:               newConfigMapLister(t),
+		verifyJWKS:                    func(string) error { return nil },
 	}
 }
 
@@ -130,6 +235,7 @@ func Test_sync(t *testing.T) {
 		{"sync-success-empty-hostname", newIngressLister(t, ingressEmptyComponentRoutes), nil, false},
 		{"ingress-config-error", newIngressLister(t), nil, true},
 		{"canonical-route-host-error", nil, newRouteLister(t, routeUnexpectedNamespaceAndName), true},
+		{"component-route-override-not-yet-admitted", newIngressLister(t, ingressStaleComponentRouteOverride), newRouteLister(t, defaultRoute), true},
 	}
 
 	for _, tt := range tests {
@@ -178,39 +284,47 @@ func Test_getIngressConfig(t *testing.T) {
 	}
 }
 
-func Test_getCanonicalRouteHost(t *testing.T) {
+func Test_getCanonicalRouteHosts(t *testing.T) {
 	tests := []struct {
-		name         string
-		host         string
-		newRouteNS   string
-		newRouteName string
+		name        string
+		routeLister routev1listers.RouteLister
 
-		expectedHost string
-		wantErr      bool
+		expectedHosts []string
+		wantErr       bool
 	}{
-		{"route-host-found", masterPublicURL, "", "", masterPublicURL, false},
-		{"no-ingress-for-host-in-route", "redhat.com", "", "", "", true},
-		{"route-not-found", masterPublicURL, "openshift-authentication", "not-oauth-openshift", "", true},
-		{"namespace-not-found", masterPublicURL, "not-openshift-authentication", "oauth-openshift", "", true},
+		{"single-admitted-host", nil, []string{masterPublicURL}, false},
+		{
+			"multi-shard-admitted",
+			newRouteLister(t, routeMultiShard),
+			[]string{masterPublicURL, "oauth-openshift.shard2.test.com"},
+			false,
+		},
+		{
+			"mixed-admitted-and-rejected",
+			newRouteLister(t, routeMixedAdmission),
+			[]string{masterPublicURL},
+			false,
+		},
+		{"no-admitted-ingress", newRouteLister(t, routeNoAdmittedIngress), nil, true},
+		{"route-not-found", newRouteLister(t, routeUnexpectedNamespaceAndName), nil, true},
 	}
 
-	c := newTestOAuthsClientsController(t)
-
 	for _, tt := range tests {
-		t.Run(tt.host, func(t *testing.T) {
-			if tt.newRouteNS != "" && tt.newRouteName != "" {
-				c.routeLister = newRouteLister(t, &routev1.Route{
-					ObjectMeta: metav1.ObjectMeta{Name: tt.newRouteName, Namespace: tt.newRouteNS},
-				})
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestOAuthsClientsController(t)
+			if tt.routeLister != nil {
+				c.routeLister = tt.routeLister
 			}
 
-			gotHost, err := c.getCanonicalRouteHost(tt.host)
+			gotHosts, err := c.getCanonicalRouteHosts()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("got error: %v; want error: %v", err, tt.wantErr)
 			}
 
-			if gotHost != tt.expectedHost {
-				t.Errorf("unexpected canonical route host; got %v; want %v", gotHost, tt.expectedHost)
+			sort.Strings(gotHosts)
+			sort.Strings(tt.expectedHosts)
+			if !reflect.DeepEqual(gotHosts, tt.expectedHosts) {
+				t.Errorf("unexpected canonical route hosts; got %v; want %v", gotHosts, tt.expectedHosts)
 			}
 		})
 	}
@@ -222,7 +336,7 @@ func Test_ensureBootstrappedOAuthClients(t *testing.T) {
 	t.Run("bootstrapped-oauth-clients-succeed", func(t *testing.T) {
 		c := newTestOAuthsClientsController(t)
 
-		if err := c.ensureBootstrappedOAuthClients(ctx, masterPublicURL); err != nil {
+		if err := c.ensureBootstrappedOAuthClients(ctx, []string{masterPublicURL}); err != nil {
 			t.Errorf("got unexpected error: %v", err)
 		}
 	})
@@ -236,7 +350,7 @@ func Test_ensureBootstrappedOAuthClients(t *testing.T) {
 		c := newTestOAuthsClientsController(t)
 		c.oauthClientClient = fakeClientset.OauthV1().OAuthClients()
 
-		if err := c.ensureBootstrappedOAuthClients(ctx, masterPublicURL); err == nil {
+		if err := c.ensureBootstrappedOAuthClients(ctx, []string{masterPublicURL}); err == nil {
 			t.Errorf("expected error but got nil")
 		}
 	})
@@ -277,6 +391,12 @@ func Test_ensureOAuthClient(t *testing.T) {
 		oauthClient       *oauthv1.OAuthClient
 		updateOAuthClient *oauthv1.OAuthClient
 
+		// wantAfterUpdate, if set, is compared against the stored client
+		// instead of updateOAuthClient. Only needed when an empty
+		// updateOAuthClient.Secret means "leave the secret alone", so the
+		// update call's input isn't what ends up persisted.
+		wantAfterUpdate *oauthv1.OAuthClient
+
 		oauthClientClient *fakeoauthclient.Clientset
 
 		wantEnsureErr bool
@@ -434,62 +554,82 @@ func Test_ensureOAuthClient(t *testing.T) {
 		{
 			name: "valid-oauth-client-when-already-exists-with-updated-empty-secret",
 			oauthClient: &oauthv1.OAuthClient{
-				ObjectMeta: metav1.ObjectMeta{Name: "already-exists-with-updated-empty-secret"},
-				Secret:     "secret",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-empty-secret"},
+				Secret:      "secret",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
 			updateOAuthClient: &oauthv1.OAuthClient{
-				Secret: "",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-empty-secret"},
+				Secret:      "",
+				GrantMethod: oauthv1.GrantHandlerAuto,
+			},
+			wantAfterUpdate: &oauthv1.OAuthClient{
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-empty-secret"},
+				Secret:      "secret",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
+			oauthClientClient: newFakeOauthClientClient(nil),
 		},
 		{
 			name: "valid-oauth-client-when-already-exists-with-updated-new-secret",
 			oauthClient: &oauthv1.OAuthClient{
-				ObjectMeta: metav1.ObjectMeta{Name: "already-exists-with-updated-new-secret"},
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-new-secret"},
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
 			updateOAuthClient: &oauthv1.OAuthClient{
-				Secret: "secret",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-new-secret"},
+				Secret:      "secret",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
+			oauthClientClient: newFakeOauthClientClient(nil),
 		},
 		{
 			name: "valid-oauth-client-when-already-exists-with-updated-longer-secret",
 			oauthClient: &oauthv1.OAuthClient{
-				ObjectMeta: metav1.ObjectMeta{Name: "already-exists-with-updated-longer-secret"},
-				Secret:     "secret",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-longer-secret"},
+				Secret:      "secret",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
 			updateOAuthClient: &oauthv1.OAuthClient{
-				Secret: "secretbutlonger",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-longer-secret"},
+				Secret:      "secretbutlonger",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
+			oauthClientClient: newFakeOauthClientClient(nil),
 		},
 		{
 			name: "valid-oauth-client-when-already-exists-with-updated-same-length-secret",
 			oauthClient: &oauthv1.OAuthClient{
-				ObjectMeta: metav1.ObjectMeta{Name: "already-exists-with-updated-same-length-secret"},
-				Secret:     "secret",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-same-length-secret"},
+				Secret:      "secret",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
 			updateOAuthClient: &oauthv1.OAuthClient{
-				Secret: "terces",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-same-length-secret"},
+				Secret:      "terces",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
+			oauthClientClient: newFakeOauthClientClient(nil),
 		},
 		{
 			name: "valid-oauth-client-when-already-exists-with-updated-shorter-secret",
 			oauthClient: &oauthv1.OAuthClient{
-				ObjectMeta: metav1.ObjectMeta{Name: "already-exists-with-updated-shorter-secret"},
-				Secret:     "loooooooooooooongsecret",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-shorter-secret"},
+				Secret:      "loooooooooooooongsecret",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
 			updateOAuthClient: &oauthv1.OAuthClient{
-				Secret: "secret",
+				ObjectMeta:  metav1.ObjectMeta{Name: "already-exists-with-updated-shorter-secret"},
+				Secret:      "secret",
+				GrantMethod: oauthv1.GrantHandlerAuto,
 			},
+			oauthClientClient: newFakeOauthClientClient(nil),
 		},
 	}
 
 	ctx := context.Background()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.updateOAuthClient != nil && tt.updateOAuthClient.Secret != "" && len(tt.updateOAuthClient.Secret) <= len(tt.oauthClient.Secret) {
-				// TODO: ensureOAuthClient won't update the secret when it's the same length or shorter (but non-empty); skip test until fixed
-				t.SkipNow()
-			}
-
 			c := newTestOAuthsClientsController(t)
 			c.oauthClientClient = tt.oauthClientClient.OauthV1().OAuthClients()
 
@@ -514,7 +654,11 @@ func Test_ensureOAuthClient(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				if !equality.Semantic.DeepEqual(tt.updateOAuthClient, updatedClient) {
+				want := tt.updateOAuthClient
+				if tt.wantAfterUpdate != nil {
+					want = tt.wantAfterUpdate
+				}
+				if !equality.Semantic.DeepEqual(want, updatedClient) {
 					t.Errorf("updated client does not equal the expected one")
 					return
 				}
@@ -549,3 +693,523 @@ func assertOAuthClient(ctx context.Context, t *testing.T, c *oauthsClientsContro
 		t.Errorf("ScopeRestrictions got: %v, want: %v", got.ScopeRestrictions, expected.ScopeRestrictions)
 	}
 }
+
+func Test_secretsEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"equal", "secret", "secret", true},
+		{"different-same-length", "secret", "terces", false},
+		{"different-length", "secret", "secretbutlonger", false},
+		{"both-empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := secretsEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("got %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ensureOAuthClient_pkceDowngrade(t *testing.T) {
+	ctx := context.Background()
+
+	s256Only := oauthv1.OAuthClient{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pkce-client",
+			Annotations: map[string]string{
+				requirePKCEAnnotation:             "true",
+				allowedChallengeMethodsAnnotation: pkceMethodS256,
+			},
+		},
+		GrantMethod: oauthv1.GrantHandlerAuto,
+	}
+
+	fakeClientset := fakeoauthclient.NewSimpleClientset()
+	client := fakeClientset.OauthV1().OAuthClients()
+
+	if err := ensureOAuthClient(ctx, client, s256Only); err != nil {
+		t.Fatalf("got unexpected error creating client: %v", err)
+	}
+
+	t.Run("downgrade-to-plain-rejected", func(t *testing.T) {
+		downgraded := s256Only.DeepCopy()
+		downgraded.Annotations[allowedChallengeMethodsAnnotation] = pkceMethodPlain
+
+		if err := ensureOAuthClient(ctx, client, *downgraded); err == nil {
+			t.Errorf("expected downgrading from S256 to plain to be rejected")
+		}
+
+		unchanged, err := client.Get(ctx, s256Only.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if unchanged.Annotations[allowedChallengeMethodsAnnotation] != pkceMethodS256 {
+			t.Errorf("allowed challenge methods changed despite rejected downgrade")
+		}
+	})
+
+	t.Run("widening-to-both-methods-rejected", func(t *testing.T) {
+		widened := s256Only.DeepCopy()
+		widened.Annotations[allowedChallengeMethodsAnnotation] = pkceMethodS256 + "," + pkceMethodPlain
+
+		if err := ensureOAuthClient(ctx, client, *widened); err == nil {
+			t.Errorf("expected adding plain alongside S256 to be rejected")
+		}
+	})
+
+	t.Run("drops-require-pkce-rejected", func(t *testing.T) {
+		dropped := s256Only.DeepCopy()
+		delete(dropped.Annotations, requirePKCEAnnotation)
+		delete(dropped.Annotations, allowedChallengeMethodsAnnotation)
+
+		if err := ensureOAuthClient(ctx, client, *dropped); err == nil {
+			t.Errorf("expected dropping requirePKCE entirely to be rejected")
+		}
+
+		unchanged, err := client.Get(ctx, s256Only.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if unchanged.Annotations[requirePKCEAnnotation] != "true" {
+			t.Errorf("requirePKCE annotation changed despite rejected downgrade")
+		}
+	})
+}
+
+func Test_ensureOAuthClient_scheduledRotation(t *testing.T) {
+	ctx := context.Background()
+	required := oauthv1.OAuthClient{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "rotated-client",
+			Annotations: map[string]string{rotateAfterAnnotation: "1h"},
+		},
+		GrantMethod: oauthv1.GrantHandlerAuto,
+	}
+
+	fakeClientset := fakeoauthclient.NewSimpleClientset()
+	client := fakeClientset.OauthV1().OAuthClients()
+
+	if err := ensureOAuthClient(ctx, client, required); err != nil {
+		t.Fatalf("got unexpected error creating client: %v", err)
+	}
+
+	created, err := client.Get(ctx, required.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if len(created.Secret) == 0 {
+		t.Fatalf("expected rotation policy to generate an initial secret")
+	}
+	if _, ok := created.Annotations[lastRotatedAnnotation]; !ok {
+		t.Fatalf("expected %s annotation to be stamped on creation", lastRotatedAnnotation)
+	}
+
+	t.Run("not-yet-due", func(t *testing.T) {
+		if err := ensureOAuthClient(ctx, client, required); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		unchanged, err := client.Get(ctx, required.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if unchanged.Secret != created.Secret {
+			t.Errorf("secret rotated before the rotate-after interval elapsed")
+		}
+	})
+
+	t.Run("not-yet-due-stays-stable-across-syncs", func(t *testing.T) {
+		// A single not-due sync isn't enough to catch the annotation diff
+		// that applyOAuthClientUpdates computes between consecutive syncs,
+		// so this calls ensureOAuthClient more than once inside the
+		// not-due window.
+		for i := 0; i < 3; i++ {
+			if err := ensureOAuthClient(ctx, client, required); err != nil {
+				t.Fatalf("got unexpected error on sync %d: %v", i, err)
+			}
+		}
+
+		stable, err := client.Get(ctx, required.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if stable.Secret != created.Secret {
+			t.Errorf("secret rotated before the rotate-after interval elapsed")
+		}
+		if stable.Annotations[lastRotatedAnnotation] != created.Annotations[lastRotatedAnnotation] {
+			t.Errorf("expected %s to stay stable across not-due syncs; got %q, want %q",
+				lastRotatedAnnotation, stable.Annotations[lastRotatedAnnotation], created.Annotations[lastRotatedAnnotation])
+		}
+	})
+
+	t.Run("not-due-policy-change-takes-effect", func(t *testing.T) {
+		changedPolicy := required.DeepCopy()
+		changedPolicy.Annotations[rotateAfterAnnotation] = "2h"
+
+		if err := ensureOAuthClient(ctx, client, *changedPolicy); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		updated, err := client.Get(ctx, required.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if updated.Secret != created.Secret {
+			t.Errorf("secret rotated before the rotate-after interval elapsed")
+		}
+		if updated.Annotations[rotateAfterAnnotation] != "2h" {
+			t.Errorf("expected a changed %s to take effect even while not due; got %q",
+				rotateAfterAnnotation, updated.Annotations[rotateAfterAnnotation])
+		}
+		if updated.Annotations[lastRotatedAnnotation] != created.Annotations[lastRotatedAnnotation] {
+			t.Errorf("expected %s to stay stable while not due", lastRotatedAnnotation)
+		}
+	})
+
+	t.Run("due", func(t *testing.T) {
+		stale := created.DeepCopy()
+		stale.Annotations[lastRotatedAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		if _, err := client.Update(ctx, stale, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("got unexpected error priming stale rotation timestamp: %v", err)
+		}
+
+		if err := ensureOAuthClient(ctx, client, required); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		rotated, err := client.Get(ctx, required.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if rotated.Secret == created.Secret {
+			t.Errorf("expected secret to be rotated once the rotate-after interval elapsed")
+		}
+	})
+}
+
+func Test_ensureM2MOAuthClients(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled-without-issuers", func(t *testing.T) {
+		c := newTestOAuthsClientsController(t)
+
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		if _, err := c.oauthClientClient.Get(ctx, openShiftM2MClientID, metav1.GetOptions{}); err == nil {
+			t.Errorf("expected no m2m oauth client to be created when no issuers are configured")
+		}
+	})
+
+	t.Run("issuer-added", func(t *testing.T) {
+		c := newTestOAuthsClientsController(t)
+		c.tokenTrustVerification = TokenTrustVerification{
+			IssuerAllowlist:           []string{"https://issuer.example.com"},
+			JWKSURL:                   "https://issuer.example.com/jwks",
+			TokenTrustCacheExpiration: time.Hour,
+		}
+
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		gotClient, err := c.oauthClientClient.Get(ctx, openShiftM2MClientID, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected m2m oauth client to be created: %v", err)
+		}
+		if gotClient.Annotations[tokenTrustIssuersAnnotation] != "https://issuer.example.com" {
+			t.Errorf("unexpected %s annotation: %v", tokenTrustIssuersAnnotation, gotClient.Annotations)
+		}
+
+		gotConfigMap, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Get(ctx, trustedIssuersConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected trusted issuers configmap to be created: %v", err)
+		}
+		if gotConfigMap.Data["issuers"] != "https://issuer.example.com" {
+			t.Errorf("unexpected issuers data: %v", gotConfigMap.Data)
+		}
+		if _, ok := gotConfigMap.Annotations[tokenTrustSyncedAtAnnotation]; !ok {
+			t.Errorf("expected %s annotation to be stamped on creation", tokenTrustSyncedAtAnnotation)
+		}
+	})
+
+	t.Run("issuer-removed", func(t *testing.T) {
+		c := newTestOAuthsClientsController(t)
+		c.tokenTrustVerification = TokenTrustVerification{
+			IssuerAllowlist:           []string{"https://issuer-a.example.com", "https://issuer-b.example.com"},
+			JWKSURL:                   "https://issuer.example.com/jwks",
+			TokenTrustCacheExpiration: time.Hour,
+		}
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		c.tokenTrustVerification.IssuerAllowlist = []string{"https://issuer-a.example.com"}
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		gotConfigMap, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Get(ctx, trustedIssuersConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if gotConfigMap.Data["issuers"] != "https://issuer-a.example.com" {
+			t.Errorf("expected removed issuer to be dropped from configmap, got: %v", gotConfigMap.Data["issuers"])
+		}
+	})
+
+	t.Run("cache-not-expired-skips-resync", func(t *testing.T) {
+		c := newTestOAuthsClientsController(t)
+		c.tokenTrustVerification = TokenTrustVerification{
+			IssuerAllowlist:           []string{"https://issuer.example.com"},
+			JWKSURL:                   "https://issuer.example.com/jwks",
+			TokenTrustCacheExpiration: time.Hour,
+		}
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		verifyCalls := 0
+		c.verifyJWKS = func(string) error {
+			verifyCalls++
+			return nil
+		}
+
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if verifyCalls != 0 {
+			t.Errorf("expected JWKS re-verification to be skipped while the cache is fresh, got %d calls", verifyCalls)
+		}
+	})
+
+	t.Run("cache-expired-triggers-resync", func(t *testing.T) {
+		c := newTestOAuthsClientsController(t)
+		c.tokenTrustVerification = TokenTrustVerification{
+			IssuerAllowlist:           []string{"https://issuer.example.com"},
+			JWKSURL:                   "https://issuer.example.com/jwks",
+			TokenTrustCacheExpiration: time.Hour,
+		}
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		stale, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Get(ctx, trustedIssuersConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		stale = stale.DeepCopy()
+		stale.Annotations[tokenTrustSyncedAtAnnotation] = time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+		if _, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Update(ctx, stale, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("got unexpected error priming stale sync timestamp: %v", err)
+		}
+
+		verifyCalls := 0
+		c.verifyJWKS = func(string) error {
+			verifyCalls++
+			return nil
+		}
+
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if verifyCalls != 1 {
+			t.Errorf("expected JWKS re-verification once the cache expired, got %d calls", verifyCalls)
+		}
+
+		refreshed, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Get(ctx, trustedIssuersConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if refreshed.Annotations[tokenTrustSyncedAtAnnotation] == stale.Annotations[tokenTrustSyncedAtAnnotation] {
+			t.Errorf("expected sync timestamp to be refreshed once the cache expired")
+		}
+	})
+
+	t.Run("issuer-allowlist-emptied-tears-down-client-and-configmap", func(t *testing.T) {
+		c := newTestOAuthsClientsController(t)
+		c.tokenTrustVerification = TokenTrustVerification{
+			IssuerAllowlist:           []string{"https://issuer.example.com"},
+			JWKSURL:                   "https://issuer.example.com/jwks",
+			TokenTrustCacheExpiration: time.Hour,
+		}
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		c.tokenTrustVerification = TokenTrustVerification{}
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		if _, err := c.oauthClientClient.Get(ctx, openShiftM2MClientID, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("expected m2m oauth client to be deleted once the issuer allowlist is emptied, got err: %v", err)
+		}
+		if _, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Get(ctx, trustedIssuersConfigMapName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("expected trusted issuers configmap to be deleted once the issuer allowlist is emptied, got err: %v", err)
+		}
+
+		// Disabling again once everything is already gone must be a no-op,
+		// not an error.
+		if err := c.ensureM2MOAuthClients(ctx); err != nil {
+			t.Fatalf("got unexpected error re-disabling an already torn down M2M client: %v", err)
+		}
+	})
+
+	t.Run("jwks-fetch-failure-rejected", func(t *testing.T) {
+		c := newTestOAuthsClientsController(t)
+		c.tokenTrustVerification = TokenTrustVerification{
+			IssuerAllowlist:           []string{"https://issuer.example.com"},
+			JWKSURL:                   "https://unreachable.example.com/jwks",
+			TokenTrustCacheExpiration: time.Hour,
+		}
+		c.verifyJWKS = func(string) error {
+			return fmt.Errorf("dial tcp: connection refused")
+		}
+
+		if err := c.ensureM2MOAuthClients(ctx); err == nil {
+			t.Errorf("expected error when the JWKS endpoint is unreachable")
+		}
+
+		if _, err := c.configMapClient.ConfigMaps(oauthServerNamespace).Get(ctx, trustedIssuersConfigMapName, metav1.GetOptions{}); err == nil {
+			t.Errorf("expected trusted issuers configmap not to be created when JWKS verification fails")
+		}
+	})
+}
+
+func Test_connectorCallbackURL(t *testing.T) {
+	got := connectorCallbackURL(masterPublicURL, "github-connector")
+	want := "https://" + masterPublicURL + "/oauth2callback/github-connector"
+	if got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func Test_oauthClientRegistrationToClient(t *testing.T) {
+	tests := []struct {
+		name         string
+		registration *oauthclientregistrationv1alpha1.OAuthClientRegistration
+		want         oauthv1.OAuthClient
+	}{
+		{
+			"plain-client",
+			&oauthclientregistrationv1alpha1.OAuthClientRegistration{
+				ObjectMeta: metav1.ObjectMeta{Name: "plain-client"},
+				Spec: oauthclientregistrationv1alpha1.OAuthClientRegistrationSpec{
+					Secret:       "s3cr3t",
+					GrantMethod:  oauthv1.GrantHandlerAuto,
+					RedirectURIs: []string{"https://example.com/callback"},
+				},
+			},
+			oauthv1.OAuthClient{
+				ObjectMeta:   metav1.ObjectMeta{Name: "plain-client"},
+				Secret:       "s3cr3t",
+				RedirectURIs: []string{"https://example.com/callback"},
+				GrantMethod:  oauthv1.GrantHandlerAuto,
+			},
+		},
+		{
+			"requires-pkce-default-methods",
+			&oauthclientregistrationv1alpha1.OAuthClientRegistration{
+				ObjectMeta: metav1.ObjectMeta{Name: "pkce-client"},
+				Spec: oauthclientregistrationv1alpha1.OAuthClientRegistrationSpec{
+					GrantMethod: oauthv1.GrantHandlerAuto,
+					RequirePKCE: true,
+				},
+			},
+			oauthv1.OAuthClient{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pkce-client",
+					Annotations: map[string]string{
+						requirePKCEAnnotation:             "true",
+						allowedChallengeMethodsAnnotation: pkceMethodS256,
+					},
+				},
+				GrantMethod: oauthv1.GrantHandlerAuto,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := oauthClientRegistrationToClient(tt.registration)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v; want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ensureRegisteredOAuthClients(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("external-connector-gets-callback-redirect-uris", func(t *testing.T) {
+		registration := &oauthclientregistrationv1alpha1.OAuthClientRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "github-connector"},
+			Spec: oauthclientregistrationv1alpha1.OAuthClientRegistrationSpec{
+				GrantMethod: oauthv1.GrantHandlerAuto,
+				ExternalConnector: &oauthclientregistrationv1alpha1.ExternalConnector{
+					Type:                    oauthclientregistrationv1alpha1.ExternalConnectorTypeGitHub,
+					ClientIDSecretRef:       "github-secret",
+					ClientIDSecretNamespace: oauthServerNamespace,
+				},
+			},
+		}
+
+		c := newTestOAuthsClientsController(t)
+		c.oauthClientRegistrationLister = newOAuthClientRegistrationLister(t, registration)
+
+		if err := c.ensureRegisteredOAuthClients(ctx, []string{masterPublicURL}); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		got, err := c.oauthClientClient.Get(ctx, registration.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected registered oauth client to be created: %v", err)
+		}
+
+		want := []string{connectorCallbackURL(masterPublicURL, registration.Name)}
+		if !reflect.DeepEqual(got.RedirectURIs, want) {
+			t.Errorf("unexpected redirect URIs; got %v; want %v", got.RedirectURIs, want)
+		}
+	})
+
+	t.Run("explicit-redirect-uris-not-overridden", func(t *testing.T) {
+		registration := &oauthclientregistrationv1alpha1.OAuthClientRegistration{
+			ObjectMeta: metav1.ObjectMeta{Name: "explicit-client"},
+			Spec: oauthclientregistrationv1alpha1.OAuthClientRegistrationSpec{
+				GrantMethod:  oauthv1.GrantHandlerAuto,
+				RedirectURIs: []string{"https://example.com/callback"},
+				ExternalConnector: &oauthclientregistrationv1alpha1.ExternalConnector{
+					Type:                    oauthclientregistrationv1alpha1.ExternalConnectorTypeOIDC,
+					IssuerURL:               "https://issuer.example.com",
+					ClientIDSecretRef:       "oidc-secret",
+					ClientIDSecretNamespace: oauthServerNamespace,
+				},
+			},
+		}
+
+		c := newTestOAuthsClientsController(t)
+		c.oauthClientRegistrationLister = newOAuthClientRegistrationLister(t, registration)
+
+		if err := c.ensureRegisteredOAuthClients(ctx, []string{masterPublicURL}); err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+
+		got, err := c.oauthClientClient.Get(ctx, registration.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected registered oauth client to be created: %v", err)
+		}
+
+		want := []string{"https://example.com/callback"}
+		if !reflect.DeepEqual(got.RedirectURIs, want) {
+			t.Errorf("unexpected redirect URIs; got %v; want %v", got.RedirectURIs, want)
+		}
+	})
+}